@@ -0,0 +1,36 @@
+// Package config carries the runtime deploy profile shared across
+// Bytebase's server components.
+package config
+
+import "time"
+
+// Profile holds operator-tunable settings resolved once at startup and
+// threaded into the components that need them.
+type Profile struct {
+	// DeployID identifies this replica within the fleet, used wherever a
+	// component needs to distinguish itself from its peers (e.g. owning a
+	// lease).
+	DeployID string
+
+	// SlowQuerySync groups the operator-tunable settings for the slow
+	// query sync runner (see backend/runner/slowquerysync), kept in their
+	// own nested struct so that feature's knobs don't have to claim
+	// top-level names on the shared Profile.
+	SlowQuerySync SlowQuerySyncProfile
+}
+
+// SlowQuerySyncProfile is the slow query sync runner's slice of Profile.
+type SlowQuerySyncProfile struct {
+	// LeaseTTL bounds how long a slow query sync node holds a claimed
+	// instance lease before another replica may reclaim it. Zero means the
+	// caller should fall back to its own default.
+	LeaseTTL time.Duration
+	// BatchSize bounds how many instances a single replica claims per
+	// lease-claim call. Zero means the caller should fall back to its own
+	// default.
+	BatchSize int
+	// BackoffBase is the base of the exponential backoff applied to a
+	// failing instance's next_run_at. Zero means the caller should fall
+	// back to its own default.
+	BackoffBase time.Duration
+}
@@ -0,0 +1,301 @@
+// Package dbmiddleware wraps the store package's internal *sql.DB/*sql.Tx
+// usage with observability: OpenTelemetry spans, Prometheus histograms, and
+// slog warnings for statements that run longer than a configurable
+// threshold. It mirrors the instrumentation Bytebase already applies to
+// external database connections, but for Bytebase's own metadata database.
+package dbmiddleware
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/bytebase/bytebase/backend/common/log"
+)
+
+const tracerName = "github.com/bytebase/bytebase/backend/component/dbmiddleware"
+
+type opKey struct{}
+
+// WithOp attaches a caller-provided operation name to ctx, used to label the
+// span, histogram, and slow-statement log line emitted for queries made with
+// this context. Callsites that don't set one fall back to "unknown".
+func WithOp(ctx context.Context, op string) context.Context {
+	return context.WithValue(ctx, opKey{}, op)
+}
+
+func opFromContext(ctx context.Context) string {
+	if op, ok := ctx.Value(opKey{}).(string); ok && op != "" {
+		return op
+	}
+	return "unknown"
+}
+
+// SlowStatement is a single capture passed to an OnSlowStatement callback.
+type SlowStatement struct {
+	Op       string
+	Query    string
+	Duration time.Duration
+	At       time.Time
+}
+
+// Options configures DB. New starts from the package defaults, which enable
+// every concern; use the With* functions to disable or tune individual
+// concerns independently.
+type Options struct {
+	tracingEnabled  bool
+	metricsEnabled  bool
+	slowThreshold   time.Duration
+	slowLogEnabled  bool
+	onSlowStatement func(SlowStatement)
+	topNSlow        int
+}
+
+// Option mutates Options.
+type Option func(*Options)
+
+// WithoutTracing disables OpenTelemetry span emission.
+func WithoutTracing() Option { return func(o *Options) { o.tracingEnabled = false } }
+
+// WithoutMetrics disables Prometheus histogram emission.
+func WithoutMetrics() Option { return func(o *Options) { o.metricsEnabled = false } }
+
+// WithoutSlowLog disables the slog.Warn emitted when a statement exceeds the
+// slow threshold.
+func WithoutSlowLog() Option { return func(o *Options) { o.slowLogEnabled = false } }
+
+// WithSlowThreshold overrides the default slow-statement threshold.
+func WithSlowThreshold(d time.Duration) Option {
+	return func(o *Options) { o.slowThreshold = d }
+}
+
+// WithSlowStatementCapture registers a callback invoked for every statement
+// that exceeds the slow threshold, and bounds how many of the slowest
+// statements SlowestStatements retains in memory.
+func WithSlowStatementCapture(topN int, fn func(SlowStatement)) Option {
+	return func(o *Options) {
+		o.topNSlow = topN
+		o.onSlowStatement = fn
+	}
+}
+
+func defaultOptions() *Options {
+	return &Options{
+		tracingEnabled: true,
+		metricsEnabled: true,
+		slowLogEnabled: true,
+		slowThreshold:  200 * time.Millisecond,
+		topNSlow:       20,
+	}
+}
+
+var queryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "bytebase",
+	Subsystem: "store",
+	Name:      "query_duration_seconds",
+	Help:      "Duration of internal store database operations.",
+	Buckets:   prometheus.DefBuckets,
+}, []string{"op", "outcome"})
+
+func init() {
+	prometheus.MustRegister(queryDuration)
+}
+
+// DB wraps *sql.DB with observability. It exposes the same QueryContext/
+// ExecContext/QueryRowContext/BeginTx surface as *sql.DB so store.New can
+// substitute it in without changing any callsite.
+type DB struct {
+	db   *sql.DB
+	opts *Options
+
+	mu      sync.Mutex
+	slowest []SlowStatement
+}
+
+// New wraps db, applying opts over the package defaults.
+func New(db *sql.DB, opts ...Option) *DB {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &DB{db: db, opts: o}
+}
+
+// Tx wraps *sql.Tx with the same observability as DB.
+type Tx struct {
+	tx   *sql.Tx
+	opts *DB
+}
+
+func (d *DB) instrument(ctx context.Context, query string, fn func(ctx context.Context) error) error {
+	op := opFromContext(ctx)
+
+	if d.opts.tracingEnabled {
+		var span trace.Span
+		ctx, span = otel.Tracer(tracerName).Start(ctx, op, trace.WithAttributes(
+			attribute.String("db.operation", op),
+		))
+		defer span.End()
+		start := time.Now()
+		err := fn(ctx)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		d.finish(op, query, start, err)
+		return err
+	}
+
+	start := time.Now()
+	err := fn(ctx)
+	d.finish(op, query, start, err)
+	return err
+}
+
+func (d *DB) finish(op, query string, start time.Time, err error) {
+	duration := time.Since(start)
+
+	if d.opts.metricsEnabled {
+		outcome := "ok"
+		if err != nil {
+			outcome = "error"
+		}
+		queryDuration.WithLabelValues(op, outcome).Observe(duration.Seconds())
+	}
+
+	if duration < d.opts.slowThreshold {
+		return
+	}
+
+	if d.opts.slowLogEnabled {
+		slog.Warn("slow internal store query",
+			slog.String("op", op),
+			slog.Duration("duration", duration),
+			log.BBError(err))
+	}
+
+	statement := SlowStatement{Op: op, Query: query, Duration: duration, At: start}
+	d.recordSlow(statement)
+	if d.opts.onSlowStatement != nil {
+		d.opts.onSlowStatement(statement)
+	}
+}
+
+func (d *DB) recordSlow(s SlowStatement) {
+	if d.opts.topNSlow <= 0 {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.slowest = append(d.slowest, s)
+	sort.Slice(d.slowest, func(i, j int) bool { return d.slowest[i].Duration > d.slowest[j].Duration })
+	if len(d.slowest) > d.opts.topNSlow {
+		d.slowest = d.slowest[:d.opts.topNSlow]
+	}
+}
+
+// SlowestStatements returns a snapshot of the top-N slowest statements
+// observed so far, for surfacing in the admin console.
+func (d *DB) SlowestStatements() []SlowStatement {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	out := make([]SlowStatement, len(d.slowest))
+	copy(out, d.slowest)
+	return out
+}
+
+// QueryContext mirrors (*sql.DB).QueryContext.
+func (d *DB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	var rows *sql.Rows
+	err := d.instrument(ctx, query, func(ctx context.Context) error {
+		var err error
+		rows, err = d.db.QueryContext(ctx, query, args...)
+		return err
+	})
+	return rows, err
+}
+
+// QueryRowContext mirrors (*sql.DB).QueryRowContext. The underlying call
+// cannot fail synchronously, so the duration is still measured around the
+// call that prepares and sends the query.
+func (d *DB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	var row *sql.Row
+	_ = d.instrument(ctx, query, func(ctx context.Context) error {
+		row = d.db.QueryRowContext(ctx, query, args...)
+		return row.Err()
+	})
+	return row
+}
+
+// ExecContext mirrors (*sql.DB).ExecContext.
+func (d *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	var result sql.Result
+	err := d.instrument(ctx, query, func(ctx context.Context) error {
+		var err error
+		result, err = d.db.ExecContext(ctx, query, args...)
+		return err
+	})
+	return result, err
+}
+
+// BeginTx mirrors (*sql.DB).BeginTx, returning a *Tx that carries the same
+// instrumentation over to the statements run within it.
+func (d *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	var tx *sql.Tx
+	err := d.instrument(ctx, "BEGIN", func(ctx context.Context) error {
+		var err error
+		tx, err = d.db.BeginTx(ctx, opts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{tx: tx, opts: d}, nil
+}
+
+// QueryContext mirrors (*sql.Tx).QueryContext.
+func (t *Tx) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	var rows *sql.Rows
+	err := t.opts.instrument(ctx, query, func(ctx context.Context) error {
+		var err error
+		rows, err = t.tx.QueryContext(ctx, query, args...)
+		return err
+	})
+	return rows, err
+}
+
+// QueryRowContext mirrors (*sql.Tx).QueryRowContext.
+func (t *Tx) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	var row *sql.Row
+	_ = t.opts.instrument(ctx, query, func(ctx context.Context) error {
+		row = t.tx.QueryRowContext(ctx, query, args...)
+		return row.Err()
+	})
+	return row
+}
+
+// ExecContext mirrors (*sql.Tx).ExecContext.
+func (t *Tx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	var result sql.Result
+	err := t.opts.instrument(ctx, query, func(ctx context.Context) error {
+		var err error
+		result, err = t.tx.ExecContext(ctx, query, args...)
+		return err
+	})
+	return result, err
+}
+
+// Commit mirrors (*sql.Tx).Commit.
+func (t *Tx) Commit() error { return t.tx.Commit() }
+
+// Rollback mirrors (*sql.Tx).Rollback.
+func (t *Tx) Rollback() error { return t.tx.Rollback() }
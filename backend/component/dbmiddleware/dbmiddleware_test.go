@@ -0,0 +1,141 @@
+package dbmiddleware
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBRecordSlowRetainsTopN(t *testing.T) {
+	tests := []struct {
+		name         string
+		topNSlow     int
+		durations    []time.Duration
+		wantRetained []time.Duration
+	}{
+		{
+			name:         "fewer statements than the cap keeps them all, sorted descending",
+			topNSlow:     5,
+			durations:    []time.Duration{time.Second, 3 * time.Second, 2 * time.Second},
+			wantRetained: []time.Duration{3 * time.Second, 2 * time.Second, time.Second},
+		},
+		{
+			name:         "more statements than the cap keeps only the slowest",
+			topNSlow:     2,
+			durations:    []time.Duration{time.Second, 3 * time.Second, 2 * time.Second},
+			wantRetained: []time.Duration{3 * time.Second, 2 * time.Second},
+		},
+		{
+			name:         "zero cap disables retention entirely",
+			topNSlow:     0,
+			durations:    []time.Duration{time.Second, 3 * time.Second},
+			wantRetained: []time.Duration{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &DB{opts: &Options{topNSlow: tt.topNSlow}}
+			for i, duration := range tt.durations {
+				d.recordSlow(SlowStatement{Op: "test", Duration: duration, At: time.Unix(int64(i), 0)})
+			}
+
+			got := d.SlowestStatements()
+			gotDurations := make([]time.Duration, 0, len(got))
+			for _, s := range got {
+				gotDurations = append(gotDurations, s.Duration)
+			}
+			require.Equal(t, tt.wantRetained, gotDurations)
+		})
+	}
+}
+
+func TestOptions(t *testing.T) {
+	tests := []struct {
+		name               string
+		opts               []Option
+		wantTracingEnabled bool
+		wantMetricsEnabled bool
+		wantSlowLogEnabled bool
+		wantSlowThreshold  time.Duration
+		wantTopNSlow       int
+	}{
+		{
+			name:               "zero options keeps every default",
+			wantTracingEnabled: true,
+			wantMetricsEnabled: true,
+			wantSlowLogEnabled: true,
+			wantSlowThreshold:  200 * time.Millisecond,
+			wantTopNSlow:       20,
+		},
+		{
+			name:               "WithoutTracing disables only tracing",
+			opts:               []Option{WithoutTracing()},
+			wantTracingEnabled: false,
+			wantMetricsEnabled: true,
+			wantSlowLogEnabled: true,
+			wantSlowThreshold:  200 * time.Millisecond,
+			wantTopNSlow:       20,
+		},
+		{
+			name:               "WithoutMetrics disables only metrics",
+			opts:               []Option{WithoutMetrics()},
+			wantTracingEnabled: true,
+			wantMetricsEnabled: false,
+			wantSlowLogEnabled: true,
+			wantSlowThreshold:  200 * time.Millisecond,
+			wantTopNSlow:       20,
+		},
+		{
+			name:               "WithoutSlowLog disables only the slow-statement log",
+			opts:               []Option{WithoutSlowLog()},
+			wantTracingEnabled: true,
+			wantMetricsEnabled: true,
+			wantSlowLogEnabled: false,
+			wantSlowThreshold:  200 * time.Millisecond,
+			wantTopNSlow:       20,
+		},
+		{
+			name:               "disabling every concern independently combines",
+			opts:               []Option{WithoutTracing(), WithoutMetrics(), WithoutSlowLog()},
+			wantTracingEnabled: false,
+			wantMetricsEnabled: false,
+			wantSlowLogEnabled: false,
+			wantSlowThreshold:  200 * time.Millisecond,
+			wantTopNSlow:       20,
+		},
+		{
+			name:               "WithSlowThreshold overrides the default threshold",
+			opts:               []Option{WithSlowThreshold(500 * time.Millisecond)},
+			wantTracingEnabled: true,
+			wantMetricsEnabled: true,
+			wantSlowLogEnabled: true,
+			wantSlowThreshold:  500 * time.Millisecond,
+			wantTopNSlow:       20,
+		},
+		{
+			name:               "WithSlowStatementCapture overrides topNSlow",
+			opts:               []Option{WithSlowStatementCapture(5, nil)},
+			wantTracingEnabled: true,
+			wantMetricsEnabled: true,
+			wantSlowLogEnabled: true,
+			wantSlowThreshold:  200 * time.Millisecond,
+			wantTopNSlow:       5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := defaultOptions()
+			for _, opt := range tt.opts {
+				opt(o)
+			}
+			require.Equal(t, tt.wantTracingEnabled, o.tracingEnabled)
+			require.Equal(t, tt.wantMetricsEnabled, o.metricsEnabled)
+			require.Equal(t, tt.wantSlowLogEnabled, o.slowLogEnabled)
+			require.Equal(t, tt.wantSlowThreshold, o.slowThreshold)
+			require.Equal(t, tt.wantTopNSlow, o.topNSlow)
+		})
+	}
+}
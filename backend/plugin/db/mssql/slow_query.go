@@ -0,0 +1,85 @@
+// Package mssql implements the slow query sync surface of the SQL Server
+// driver: statement statistics come from the instance-wide plan cache
+// rather than a per-database log.
+package mssql
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	storepb "github.com/bytebase/bytebase/proto/generated-go/store"
+)
+
+// CheckSlowQueryLogEnabled verifies the connected login can read
+// sys.dm_exec_query_stats, which requires the VIEW SERVER STATE permission.
+func (d *Driver) CheckSlowQueryLogEnabled(ctx context.Context) error {
+	var count int
+	if err := d.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sys.dm_exec_query_stats`).Scan(&count); err != nil {
+		return errors.Wrap(err, "sys.dm_exec_query_stats is not accessible; the login may be missing VIEW SERVER STATE")
+	}
+	return nil
+}
+
+// SyncSlowQuery returns the current contents of sys.dm_exec_query_stats,
+// SQL Server's cumulative plan cache statistics, joined to
+// sys.dm_exec_sql_text for the query text and grouped by query_hash so
+// statements differing only by literal values are fingerprinted together.
+// logDateTs is unused: like pg_stat_statements, dm_exec_query_stats
+// accumulates since the plan cache was last cleared rather than being
+// bucketed by day, which is why the caller (syncMSSQLSlowQuery) merges this
+// with the log already persisted for the day of the latest execution before
+// upserting it.
+func (d *Driver) SyncSlowQuery(ctx context.Context, _ time.Time) (map[string]*storepb.SlowQueryStatistics, error) {
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT
+			DB_NAME(t.dbid) AS database_name,
+			CONVERT(VARCHAR(64), qs.query_hash, 1) AS query_hash,
+			SUM(qs.execution_count) AS executions,
+			SUM(qs.total_elapsed_time) AS total_elapsed_time,
+			MAX(qs.max_elapsed_time) AS max_elapsed_time,
+			SUM(qs.total_rows) AS total_rows,
+			MAX(qs.last_execution_time) AS last_execution_time
+		FROM sys.dm_exec_query_stats qs
+		CROSS APPLY sys.dm_exec_sql_text(qs.sql_handle) t
+		WHERE t.dbid IS NOT NULL AND qs.query_hash IS NOT NULL
+		GROUP BY DB_NAME(t.dbid), qs.query_hash
+	`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query sys.dm_exec_query_stats")
+	}
+	defer rows.Close()
+
+	result := make(map[string]*storepb.SlowQueryStatistics)
+	for rows.Next() {
+		var databaseName, queryHash string
+		var executions, totalRows int64
+		var totalElapsedMicros, maxElapsedMicros float64
+		var lastExecutionTime time.Time
+		if err := rows.Scan(&databaseName, &queryHash, &executions, &totalElapsedMicros, &maxElapsedMicros, &totalRows, &lastExecutionTime); err != nil {
+			return nil, err
+		}
+		item := &storepb.SlowQueryStatisticsItem{
+			SqlFingerprint:   queryHash,
+			Count:            executions,
+			LatestLogTime:    timestamppb.New(lastExecutionTime),
+			TotalQueryTime:   durationpb.New(time.Duration(totalElapsedMicros) * time.Microsecond),
+			MaximumQueryTime: durationpb.New(time.Duration(maxElapsedMicros) * time.Microsecond),
+			TotalRowsSent:    totalRows,
+		}
+		statistics, ok := result[databaseName]
+		if !ok {
+			statistics = &storepb.SlowQueryStatistics{}
+			result[databaseName] = statistics
+		}
+		statistics.Items = append(statistics.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
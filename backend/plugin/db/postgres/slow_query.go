@@ -0,0 +1,21 @@
+// Package postgres implements the slow query sync surface of the Postgres
+// driver, backed by the pg_stat_statements extension.
+package postgres
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// ResetSlowQuery resets pg_stat_statements' cumulative counters for every
+// database on the instance. Callers that diff successive samples instead of
+// resetting (see deltaSlowQueryStatistics in
+// backend/runner/slowquerysync/syncer.go) don't need this; it only matters
+// when the slow query policy has AutoReset enabled.
+func (d *Driver) ResetSlowQuery(ctx context.Context) error {
+	if _, err := d.db.ExecContext(ctx, `SELECT pg_stat_statements_reset()`); err != nil {
+		return errors.Wrap(err, "failed to reset pg_stat_statements")
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// Package tidb implements the slow query sync surface of the TiDB driver.
+package tidb
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	storepb "github.com/bytebase/bytebase/proto/generated-go/store"
+)
+
+// CheckSlowQueryLogEnabled verifies the connected user can read
+// INFORMATION_SCHEMA.SLOW_QUERY, which requires the PROCESS privilege. An
+// empty slow log passes the check.
+func (d *Driver) CheckSlowQueryLogEnabled(ctx context.Context) error {
+	var count int
+	if err := d.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM INFORMATION_SCHEMA.SLOW_QUERY LIMIT 1`).Scan(&count); err != nil {
+		return errors.Wrap(err, "INFORMATION_SCHEMA.SLOW_QUERY is not accessible; the user may be missing the PROCESS privilege")
+	}
+	return nil
+}
+
+// SyncSlowQuery returns one day's worth of statement statistics from
+// INFORMATION_SCHEMA.CLUSTER_SLOW_QUERY, TiDB's cluster-wide slow log
+// aggregated across every TiDB node, bucketed by logDateTs and
+// fingerprinted on Digest.
+func (d *Driver) SyncSlowQuery(ctx context.Context, logDateTs time.Time) (map[string]*storepb.SlowQueryStatistics, error) {
+	start := logDateTs.Truncate(24 * time.Hour)
+	end := start.AddDate(0, 0, 1)
+
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT
+			DB,
+			Digest,
+			COUNT(*) AS executions,
+			SUM(Query_time) AS total_query_time,
+			MAX(Query_time) AS max_query_time,
+			SUM(Result_rows) AS total_rows,
+			MAX(Time) AS latest_time
+		FROM INFORMATION_SCHEMA.CLUSTER_SLOW_QUERY
+		WHERE Time >= ? AND Time < ? AND Digest != ''
+		GROUP BY DB, Digest
+	`, start, end)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query INFORMATION_SCHEMA.CLUSTER_SLOW_QUERY")
+	}
+	defer rows.Close()
+
+	result := make(map[string]*storepb.SlowQueryStatistics)
+	for rows.Next() {
+		var databaseName, digest string
+		var executions, totalRows int64
+		var totalQuerySeconds, maxQuerySeconds float64
+		var latestTime time.Time
+		if err := rows.Scan(&databaseName, &digest, &executions, &totalQuerySeconds, &maxQuerySeconds, &totalRows, &latestTime); err != nil {
+			return nil, err
+		}
+		item := &storepb.SlowQueryStatisticsItem{
+			SqlFingerprint:   digest,
+			Count:            executions,
+			LatestLogTime:    timestamppb.New(latestTime),
+			TotalQueryTime:   durationpb.New(time.Duration(totalQuerySeconds * float64(time.Second))),
+			MaximumQueryTime: durationpb.New(time.Duration(maxQuerySeconds * float64(time.Second))),
+			TotalRowsSent:    totalRows,
+		}
+		statistics, ok := result[databaseName]
+		if !ok {
+			statistics = &storepb.SlowQueryStatistics{}
+			result[databaseName] = statistics
+		}
+		statistics.Items = append(statistics.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
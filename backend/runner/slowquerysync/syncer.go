@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"os"
 	"sync"
 	"time"
 
@@ -27,24 +28,88 @@ const (
 	slowQuerySyncInterval = 12 * time.Hour
 	// retentionCycle is the number of days to keep slow query logs.
 	retentionCycle = 30
+
+	// defaultLeaseTTL bounds how long a claimed instance lease is held
+	// before another node may reclaim it if the owning node disappears.
+	defaultLeaseTTL = 30 * time.Minute
+	// defaultLeaseBatchSize bounds how many instances a single node claims
+	// per claim call, so one node cannot starve the rest of the fleet.
+	defaultLeaseBatchSize = 20
+	// leaseHeartbeatInterval is how often an in-flight sync refreshes its
+	// lease so a slow instance doesn't get reclaimed mid-sync.
+	leaseHeartbeatInterval = 5 * time.Minute
+	// defaultBackoffBase is the base of the exponential backoff applied to
+	// next_run_at after a failed sync.
+	defaultBackoffBase = 2 * time.Minute
+	// maxBackoff caps the exponential backoff so a persistently failing
+	// instance is still retried within a day.
+	maxBackoff = 24 * time.Hour
 )
 
-// NewSyncer creates a new slow query syncer.
+// NewSyncer creates a new slow query syncer. leaseTTL/batchSize/backoffBase
+// come from profile when set, falling back to the package defaults
+// otherwise, so operators can tune them without a rebuild.
 func NewSyncer(store *store.Store, dbFactory *dbfactory.DBFactory, stateCfg *state.State, profile *config.Profile) *Syncer {
+	nodeID := profile.DeployID
+	if nodeID == "" {
+		nodeID = nodeIdentity()
+	}
 	return &Syncer{
-		store:     store,
-		dbFactory: dbFactory,
-		stateCfg:  stateCfg,
-		profile:   profile,
+		store:       store,
+		dbFactory:   dbFactory,
+		stateCfg:    stateCfg,
+		profile:     profile,
+		nodeID:      nodeID,
+		leaseTTL:    orDefaultDuration(profile.SlowQuerySync.LeaseTTL, defaultLeaseTTL),
+		batchSize:   orDefaultInt(profile.SlowQuerySync.BatchSize, defaultLeaseBatchSize),
+		backoffBase: orDefaultDuration(profile.SlowQuerySync.BackoffBase, defaultBackoffBase),
+	}
+}
+
+// nodeIdentity returns a best-effort identifier for this replica when
+// profile.DeployID isn't set, used to own leases in slow_query_sync_lease.
+func nodeIdentity() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		return fmt.Sprintf("pid-%d", os.Getpid())
 	}
+	return hostname
 }
 
-// Syncer is the slow query syncer.
+func orDefaultDuration(v, def time.Duration) time.Duration {
+	if v <= 0 {
+		return def
+	}
+	return v
+}
+
+func orDefaultInt(v, def int) int {
+	if v <= 0 {
+		return def
+	}
+	return v
+}
+
+// Syncer is the slow query syncer. In a highly-available deployment, every
+// replica runs a Syncer; work is sharded across them via leases claimed in
+// the slow_query_sync_lease table rather than every replica syncing every
+// instance.
 type Syncer struct {
 	store     *store.Store
 	dbFactory *dbfactory.DBFactory
 	stateCfg  *state.State
 	profile   *config.Profile
+
+	// nodeID identifies this replica when claiming and heartbeating leases.
+	nodeID string
+	// leaseTTL bounds how long a claimed lease is held before it can be
+	// reclaimed by another node if this one disappears mid-sync.
+	leaseTTL time.Duration
+	// batchSize bounds how many instances this node claims per claim call.
+	batchSize int
+	// backoffBase is the base of the exponential backoff applied to a
+	// failing instance's next_run_at.
+	backoffBase time.Duration
 }
 
 // Run will run the slow query syncer.
@@ -63,7 +128,7 @@ func (s *Syncer) Run(ctx context.Context, wg *sync.WaitGroup) {
 			s.syncSlowQuery(ctx, message)
 		case <-ticker.C:
 			slog.Debug("Slow query syncer received tick")
-			s.syncSlowQuery(ctx, nil)
+			s.syncLeasedInstances(ctx)
 		}
 	}
 }
@@ -107,6 +172,119 @@ func (s *Syncer) syncSlowQuery(ctx context.Context, message *state.InstanceSlowQ
 	instanceWG.Wait()
 }
 
+// syncLeasedInstances drains this node's share of due instances within the
+// current tick: it claims and syncs up to s.batchSize instances at a time
+// via an atomic UPDATE ... RETURNING in the store, repeating until a claim
+// comes back with fewer than s.batchSize instances. Without draining, a fleet larger than
+// batchSize * live_node_count would fall further and further behind every
+// tick instead of catching up.
+func (s *Syncer) syncLeasedInstances(ctx context.Context) {
+	defer func() {
+		if r := recover(); r != nil {
+			err, ok := r.(error)
+			if !ok {
+				err = errors.Errorf("%v", r)
+			}
+			slog.Error("slow query syncer PANIC RECOVER", log.BBError(err), log.BBStack("panic-stack"))
+		}
+	}()
+
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		instanceIDs, err := s.store.ClaimDueInstanceSlowQueryLeases(ctx, s.nodeID, s.leaseTTL, s.batchSize)
+		if err != nil {
+			slog.Error("Failed to claim slow query sync leases", log.BBError(err))
+			return
+		}
+		if len(instanceIDs) == 0 {
+			return
+		}
+		slog.Debug("Slow query syncer claimed instance leases", slog.Int("count", len(instanceIDs)), slog.String("node", s.nodeID))
+
+		var instanceWG sync.WaitGroup
+		for _, instanceID := range instanceIDs {
+			instanceWG.Add(1)
+			go func(instanceID string) {
+				defer instanceWG.Done()
+				s.syncLeasedInstance(ctx, instanceID)
+			}(instanceID)
+		}
+		instanceWG.Wait()
+
+		if len(instanceIDs) < s.batchSize {
+			// Fewer than a full batch means we've caught up with every
+			// instance that was due; the rest of the fleet is not yet due.
+			return
+		}
+	}
+}
+
+// syncLeasedInstance syncs a single leased instance, heartbeating the lease
+// while the sync is in flight, then releasing it on success or backing off
+// the next attempt on failure.
+func (s *Syncer) syncLeasedInstance(ctx context.Context, instanceID string) {
+	heartbeatCtx, cancelHeartbeat := context.WithCancel(ctx)
+	defer cancelHeartbeat()
+	go func() {
+		ticker := time.NewTicker(leaseHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-heartbeatCtx.Done():
+				return
+			case <-ticker.C:
+				if err := s.store.HeartbeatSlowQuerySyncLease(ctx, instanceID, s.nodeID, s.leaseTTL); err != nil {
+					slog.Warn("Failed to heartbeat slow query sync lease", slog.String("instance", instanceID), log.BBError(err))
+				}
+			}
+		}
+	}()
+
+	instances, err := s.store.ListInstancesV2(ctx, &store.FindInstanceMessage{ResourceID: &instanceID})
+	if err != nil || len(instances) == 0 {
+		if err == nil {
+			err = errors.Errorf("instance %s not found", instanceID)
+		}
+		slog.Debug("Failed to look up leased instance", slog.String("instance", instanceID), log.BBError(err))
+		s.backoffLease(ctx, instanceID)
+		return
+	}
+	instance := instances[0]
+	if instance.Deleted {
+		if err := s.store.ReleaseSlowQuerySyncLease(ctx, instanceID, s.nodeID, time.Now().Add(slowQuerySyncInterval)); err != nil {
+			slog.Warn("Failed to release slow query sync lease", slog.String("instance", instanceID), log.BBError(err))
+		}
+		return
+	}
+
+	if err := s.syncInstanceSlowQuery(ctx, instance); err != nil {
+		slog.Debug("Failed to sync instance slow query", slog.String("instance", instanceID), log.BBError(err))
+		s.backoffLease(ctx, instanceID)
+		return
+	}
+
+	if err := s.store.ReleaseSlowQuerySyncLease(ctx, instanceID, s.nodeID, time.Now().Add(slowQuerySyncInterval)); err != nil {
+		slog.Warn("Failed to release slow query sync lease", slog.String("instance", instanceID), log.BBError(err))
+	}
+}
+
+// backoffLease releases a failed instance's lease with an exponentially
+// growing delay before next_run_at so a persistently failing instance
+// doesn't get reclaimed and retried on every tick.
+func (s *Syncer) backoffLease(ctx context.Context, instanceID string) {
+	if err := s.store.BackoffSlowQuerySyncLease(ctx, instanceID, s.nodeID, s.backoffBase, maxBackoff); err != nil {
+		slog.Warn("Failed to back off slow query sync lease", slog.String("instance", instanceID), log.BBError(err))
+	}
+}
+
+// syncInstanceSlowQuery dispatches to the engine-specific sync routine for
+// instance. dbFactory.GetAdminDatabaseDriver already resolves instance.Engine
+// to the matching concrete driver (backend/plugin/db/<engine>), so the
+// CheckSlowQueryLogEnabled/SyncSlowQuery calls below run the real per-engine
+// statement statistics query, not a shared generic implementation.
 func (s *Syncer) syncInstanceSlowQuery(ctx context.Context, instance *store.InstanceMessage) error {
 	slowQueryPolicy, err := s.store.GetSlowQueryPolicy(ctx, instance.ResourceID)
 	if err != nil {
@@ -120,18 +298,42 @@ func (s *Syncer) syncInstanceSlowQuery(ctx context.Context, instance *store.Inst
 	case storepb.Engine_MYSQL:
 		return s.syncMySQLSlowQuery(ctx, instance)
 	case storepb.Engine_POSTGRES:
-		return s.syncPostgreSQLSlowQuery(ctx, instance)
+		return s.syncPostgreSQLSlowQuery(ctx, instance, slowQueryPolicy)
+	case storepb.Engine_ORACLE:
+		// Oracle samples DBA_HIST_SQLSTAT (see backend/plugin/db/oracle) and
+		// fingerprints on SQL_ID; the driver still hands back one day's worth
+		// of statistics at a time, so it fits the same date-bucketed
+		// scaffolding as MySQL.
+		return s.syncDateBucketedSlowQuery(ctx, instance)
+	case storepb.Engine_MSSQL:
+		// sys.dm_exec_query_stats (see backend/plugin/db/mssql) is a
+		// cumulative, instance-wide cache rather than a per-day log, so MSSQL
+		// follows the Postgres-style cumulative scaffolding instead.
+		return s.syncMSSQLSlowQuery(ctx, instance)
+	case storepb.Engine_TIDB:
+		// INFORMATION_SCHEMA.CLUSTER_SLOW_QUERY (see backend/plugin/db/tidb)
+		// is date-bucketed like MySQL's slow log, fingerprinted on Digest.
+		return s.syncDateBucketedSlowQuery(ctx, instance)
 	default:
 		return errors.Errorf("unsupported database engine: %s", instance.Engine)
 	}
 }
 
-func (s *Syncer) syncPostgreSQLSlowQuery(ctx context.Context, instance *store.InstanceMessage) error {
+// slowQuerySyncWindow deletes slow query logs older than the retention
+// cycle for instanceID and returns today and the earliest retained date, so
+// every engine-specific sync routine shares the same retention window
+// instead of recomputing it.
+func (s *Syncer) slowQuerySyncWindow(ctx context.Context, instanceID string) (time.Time, time.Time, error) {
 	today := time.Now().UTC().Truncate(24 * time.Hour)
-
 	earliestDate := today.AddDate(0, 0, -retentionCycle)
+	if err := s.store.DeleteOutdatedSlowLog(ctx, instanceID, earliestDate); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return today, earliestDate, nil
+}
 
-	if err := s.store.DeleteOutdatedSlowLog(ctx, instance.ResourceID, earliestDate); err != nil {
+func (s *Syncer) syncPostgreSQLSlowQuery(ctx context.Context, instance *store.InstanceMessage, slowQueryPolicy *store.SlowQueryPolicyMessage) error {
+	if _, _, err := s.slowQuerySyncWindow(ctx, instance.ResourceID); err != nil {
 		return err
 	}
 
@@ -192,6 +394,33 @@ func (s *Syncer) syncPostgreSQLSlowQuery(ctx context.Context, instance *store.In
 	latestLogDate = latestLogDate.Truncate(24 * time.Hour)
 	nextLogDate := latestLogDate.AddDate(0, 0, 1)
 
+	snapshot, err := s.store.GetPgSlowQuerySnapshot(ctx, instance.ResourceID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get pg slow query snapshot for instance %s", instance.ResourceID)
+	}
+	logMap, newSnapshot := deltaSlowQueryStatistics(logMap, snapshot)
+	if err := s.store.UpsertPgSlowQuerySnapshot(ctx, instance.ResourceID, newSnapshot); err != nil {
+		slog.Warn("Failed to upsert pg slow query snapshot",
+			slog.String("instance", instance.ResourceID),
+			log.BBError(err))
+	}
+
+	// The Postgres driver (backend/plugin/db/postgres) implements
+	// ResetSlowQuery; the type assertion just avoids widening db.Driver's
+	// interface with a method only Postgres needs.
+	if slowQueryPolicy.AutoReset {
+		if resetter, ok := driver.(interface{ ResetSlowQuery(ctx context.Context) error }); ok {
+			if err := resetter.ResetSlowQuery(ctx); err != nil {
+				slog.Warn("Failed to reset pg_stat_statements after diffing",
+					slog.String("instance", instance.ResourceID),
+					log.BBError(err))
+			}
+		} else {
+			slog.Warn("AutoReset is enabled but the driver does not support resetting pg_stat_statements",
+				slog.String("instance", instance.ResourceID))
+		}
+	}
+
 	for _, database := range databases {
 		statistics, exists := logMap[database.DatabaseName]
 		if !exists {
@@ -212,7 +441,7 @@ func (s *Syncer) syncPostgreSQLSlowQuery(ctx context.Context, instance *store.In
 		}
 
 		if len(logs) != 0 {
-			statistics = pgMergeSlowQueryLog(statistics, logs)
+			statistics = mergeSlowQueryLog(statistics, logs)
 		}
 		if err := s.store.UpsertSlowLog(ctx, &store.UpsertSlowLogMessage{
 			InstanceID:   instance.ResourceID,
@@ -229,7 +458,71 @@ func (s *Syncer) syncPostgreSQLSlowQuery(ctx context.Context, instance *store.In
 	return nil
 }
 
-func pgMergeSlowQueryLog(statistics *storepb.SlowQueryStatistics, logs []*v1pb.SlowQueryLog) *storepb.SlowQueryStatistics {
+// deltaSlowQueryStatistics turns a cumulative pg_stat_statements-style
+// sample into a per-window delta against the last-seen snapshot, keyed by
+// "database/queryid" (queryid == SqlFingerprint here). A query absent from
+// the snapshot is new to us and passes through unchanged; a query whose
+// counters decreased since the snapshot indicates pg_stat_statements was
+// reset or wrapped around, so the current sample becomes the fresh
+// baseline instead of going negative. It returns the delta statistics, still
+// keyed by database name, plus the new snapshot rows to persist.
+func deltaSlowQueryStatistics(logMap map[string]*storepb.SlowQueryStatistics, snapshot map[string]*store.PgSlowQuerySnapshotMessage) (map[string]*storepb.SlowQueryStatistics, []*store.PgSlowQuerySnapshotMessage) {
+	deltaMap := make(map[string]*storepb.SlowQueryStatistics, len(logMap))
+	var newSnapshot []*store.PgSlowQuerySnapshotMessage
+
+	for databaseName, statistics := range logMap {
+		var deltaItems []*storepb.SlowQueryStatisticsItem
+		for _, item := range statistics.Items {
+			current := store.PgSlowQuerySnapshotMessage{
+				DatabaseName:  databaseName,
+				QueryID:       item.SqlFingerprint,
+				Calls:         item.Count,
+				TotalExecTime: item.TotalQueryTime.AsDuration().Seconds(),
+				MaxExecTime:   item.MaximumQueryTime.AsDuration().Seconds(),
+				Rows:          item.TotalRowsSent,
+			}
+			newSnapshot = append(newSnapshot, &current)
+
+			previous, exists := snapshot[databaseName+"/"+item.SqlFingerprint]
+			deltaItem := item
+			if exists {
+				deltaCalls := current.Calls - previous.Calls
+				deltaTime := current.TotalExecTime - previous.TotalExecTime
+				deltaRows := current.Rows - previous.Rows
+				if deltaCalls < 0 || deltaTime < 0 || deltaRows < 0 {
+					// pg_stat_statements was reset (or wrapped around); the
+					// current sample is the new baseline, not a delta.
+					deltaCalls = current.Calls
+					deltaTime = current.TotalExecTime
+					deltaRows = current.Rows
+				}
+				if deltaCalls == 0 {
+					// Nothing new happened for this query this window.
+					continue
+				}
+				deltaItem = &storepb.SlowQueryStatisticsItem{
+					SqlFingerprint:   item.SqlFingerprint,
+					Count:            deltaCalls,
+					LatestLogTime:    item.LatestLogTime,
+					TotalQueryTime:   durationpb.New(time.Duration(deltaTime * float64(time.Second))),
+					MaximumQueryTime: item.MaximumQueryTime,
+					TotalRowsSent:    deltaRows,
+				}
+			}
+			deltaItems = append(deltaItems, deltaItem)
+		}
+		deltaMap[databaseName] = &storepb.SlowQueryStatistics{Items: deltaItems}
+	}
+
+	return deltaMap, newSnapshot
+}
+
+// mergeSlowQueryLog merges a driver sample with whatever was already
+// persisted for the window, keyed by SQL fingerprint. Shared by any engine
+// whose source table accumulates rather than resets per day: Postgres passes
+// the per-window delta of pg_stat_statements, MSSQL passes the cumulative
+// sys.dm_exec_query_stats sample as is.
+func mergeSlowQueryLog(statistics *storepb.SlowQueryStatistics, logs []*v1pb.SlowQueryLog) *storepb.SlowQueryStatistics {
 	status := make(map[string]*storepb.SlowQueryStatisticsItem)
 
 	for _, item := range statistics.Items {
@@ -274,12 +567,97 @@ func getLatestLogTime(logMap map[string]*storepb.SlowQueryStatistics) time.Time
 	return time.Time{}
 }
 
-func (s *Syncer) syncMySQLSlowQuery(ctx context.Context, instance *store.InstanceMessage) error {
-	today := time.Now().UTC().Truncate(24 * time.Hour)
+// syncMSSQLSlowQuery syncs sys.dm_exec_query_stats, which (like Postgres's
+// pg_stat_statements) accumulates across the whole instance rather than
+// resetting per day, so it reuses the cumulative-merge scaffolding instead
+// of the date-bucketed one. Unlike Postgres, dm_exec_query_stats isn't
+// gated behind a per-database extension, so there's no "find an enabled
+// database" step: the admin driver talks to the instance directly.
+func (s *Syncer) syncMSSQLSlowQuery(ctx context.Context, instance *store.InstanceMessage) error {
+	if _, _, err := s.slowQuerySyncWindow(ctx, instance.ResourceID); err != nil {
+		return err
+	}
 
-	earliestDate := today.AddDate(0, 0, -retentionCycle)
+	driver, err := s.dbFactory.GetAdminDatabaseDriver(ctx, instance, nil /* database */, db.ConnectionContext{})
+	if err != nil {
+		return err
+	}
+	defer driver.Close(ctx)
+	if err := driver.CheckSlowQueryLogEnabled(ctx); err != nil {
+		return err
+	}
+
+	logMap, err := driver.SyncSlowQuery(ctx, time.Now() /* logDateTs is not used; dm_exec_query_stats is cumulative */)
+	if err != nil {
+		return err
+	}
+
+	latestLogDate := getLatestLogTime(logMap)
+	if latestLogDate.IsZero() {
+		// Empty log, no need to sync.
+		return nil
+	}
+	latestLogDate = latestLogDate.Truncate(24 * time.Hour)
+	nextLogDate := latestLogDate.AddDate(0, 0, 1)
+
+	findDatabases := &store.FindDatabaseMessage{
+		InstanceID: &instance.ResourceID,
+	}
+	databases, err := s.store.ListDatabases(ctx, findDatabases)
+	if err != nil {
+		return err
+	}
+
+	for _, database := range databases {
+		statistics, exists := logMap[database.DatabaseName]
+		if !exists {
+			continue
+		}
+
+		logs, err := s.store.ListSlowQuery(ctx, &store.ListSlowQueryMessage{
+			InstanceID:   &database.InstanceID,
+			DatabaseName: &database.DatabaseName,
+			StartLogDate: &latestLogDate,
+			EndLogDate:   &nextLogDate,
+		})
+		if err != nil {
+			slog.Warn("Failed to list slow query logs",
+				slog.String("database", database.String()),
+				log.BBError(err))
+			logs = nil
+		}
+
+		if len(logs) != 0 {
+			statistics = mergeSlowQueryLog(statistics, logs)
+		}
+		if err := s.store.UpsertSlowLog(ctx, &store.UpsertSlowLogMessage{
+			InstanceID:   instance.ResourceID,
+			DatabaseName: database.DatabaseName,
+			LogDate:      latestLogDate,
+			SlowLog:      statistics,
+		}); err != nil {
+			slog.Warn("Failed to upsert slow query log",
+				slog.String("database", database.String()),
+				log.BBError(err))
+		}
+	}
+
+	return nil
+}
 
-	if err := s.store.DeleteOutdatedSlowLog(ctx, instance.ResourceID, earliestDate); err != nil {
+func (s *Syncer) syncMySQLSlowQuery(ctx context.Context, instance *store.InstanceMessage) error {
+	return s.syncDateBucketedSlowQuery(ctx, instance)
+}
+
+// syncDateBucketedSlowQuery handles engines whose slow query source is
+// naturally bucketed by day (MySQL's slow log table, Oracle's
+// DBA_HIST_SQLSTAT sampling, TiDB's CLUSTER_SLOW_QUERY view): it walks forward one
+// day at a time from the last synced date, asking the driver for that day's
+// statistics and upserting them directly, since each day's sample doesn't
+// need merging with what came before.
+func (s *Syncer) syncDateBucketedSlowQuery(ctx context.Context, instance *store.InstanceMessage) error {
+	today, earliestDate, err := s.slowQuerySyncWindow(ctx, instance.ResourceID)
+	if err != nil {
 		return err
 	}
 
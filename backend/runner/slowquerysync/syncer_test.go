@@ -0,0 +1,102 @@
+package slowquerysync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/bytebase/bytebase/backend/store"
+	storepb "github.com/bytebase/bytebase/proto/generated-go/store"
+)
+
+func TestDeltaSlowQueryStatistics(t *testing.T) {
+	item := func(fingerprint string, count int64, totalSeconds float64, rows int64) *storepb.SlowQueryStatisticsItem {
+		return &storepb.SlowQueryStatisticsItem{
+			SqlFingerprint:   fingerprint,
+			Count:            count,
+			TotalQueryTime:   durationpb.New(time.Duration(totalSeconds * float64(time.Second))),
+			MaximumQueryTime: durationpb.New(time.Second),
+			TotalRowsSent:    rows,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		logMap    map[string]*storepb.SlowQueryStatistics
+		snapshot  map[string]*store.PgSlowQuerySnapshotMessage
+		wantDelta map[string][]int64 // fingerprint -> delta count, keyed per database
+	}{
+		{
+			name: "query unseen before passes through unchanged",
+			logMap: map[string]*storepb.SlowQueryStatistics{
+				"db1": {Items: []*storepb.SlowQueryStatisticsItem{item("q1", 10, 5, 100)}},
+			},
+			snapshot: map[string]*store.PgSlowQuerySnapshotMessage{},
+			wantDelta: map[string][]int64{
+				"db1": {10},
+			},
+		},
+		{
+			name: "query with growing counters reports the delta",
+			logMap: map[string]*storepb.SlowQueryStatistics{
+				"db1": {Items: []*storepb.SlowQueryStatisticsItem{item("q1", 30, 15, 300)}},
+			},
+			snapshot: map[string]*store.PgSlowQuerySnapshotMessage{
+				"db1/q1": {DatabaseName: "db1", QueryID: "q1", Calls: 10, TotalExecTime: 5, Rows: 100},
+			},
+			wantDelta: map[string][]int64{
+				"db1": {20},
+			},
+		},
+		{
+			name: "unchanged counters produce no delta item",
+			logMap: map[string]*storepb.SlowQueryStatistics{
+				"db1": {Items: []*storepb.SlowQueryStatisticsItem{item("q1", 10, 5, 100)}},
+			},
+			snapshot: map[string]*store.PgSlowQuerySnapshotMessage{
+				"db1/q1": {DatabaseName: "db1", QueryID: "q1", Calls: 10, TotalExecTime: 5, Rows: 100},
+			},
+			wantDelta: map[string][]int64{
+				"db1": {},
+			},
+		},
+		{
+			name: "counters going backwards means a reset; current sample becomes the baseline",
+			logMap: map[string]*storepb.SlowQueryStatistics{
+				"db1": {Items: []*storepb.SlowQueryStatisticsItem{item("q1", 5, 2, 50)}},
+			},
+			snapshot: map[string]*store.PgSlowQuerySnapshotMessage{
+				"db1/q1": {DatabaseName: "db1", QueryID: "q1", Calls: 10, TotalExecTime: 5, Rows: 100},
+			},
+			wantDelta: map[string][]int64{
+				"db1": {5},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deltaMap, newSnapshot := deltaSlowQueryStatistics(tt.logMap, tt.snapshot)
+
+			for dbName, wantCounts := range tt.wantDelta {
+				statistics, ok := deltaMap[dbName]
+				require.True(t, ok)
+				gotCounts := make([]int64, 0, len(statistics.Items))
+				for _, item := range statistics.Items {
+					gotCounts = append(gotCounts, item.Count)
+				}
+				require.Equal(t, wantCounts, gotCounts)
+			}
+
+			// newSnapshot always mirrors the current sample, independent of
+			// the delta, so the next window diffs against what we just saw.
+			var totalItems int
+			for _, statistics := range tt.logMap {
+				totalItems += len(statistics.Items)
+			}
+			require.Len(t, newSnapshot, totalItems)
+		})
+	}
+}
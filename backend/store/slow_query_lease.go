@@ -0,0 +1,125 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SlowQueryLeaseMessage is a claimed lease over an instance's slow query
+// sync work, used to shard syncing across replicas.
+type SlowQueryLeaseMessage struct {
+	InstanceID string
+	OwnerID    string
+	ExpiresAt  time.Time
+	NextRunAt  time.Time
+	FailCount  int
+}
+
+// ClaimDueInstanceSlowQueryLeases atomically claims up to limit instances
+// whose slow_query_sync_lease row is unowned or expired, assigning them to
+// ownerID with the given ttl. It returns the resource IDs of the claimed
+// instances. Instances without an existing lease row are seeded with one on
+// first claim.
+func (s *Store) ClaimDueInstanceSlowQueryLeases(ctx context.Context, ownerID string, ttl time.Duration, limit int) ([]string, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO slow_query_sync_lease (instance_id, owner_id, expires_at, next_run_at, fail_count)
+		SELECT instance.resource_id, '', TIMESTAMP WITH TIME ZONE 'epoch', TIMESTAMP WITH TIME ZONE 'epoch', 0
+		FROM instance
+		WHERE instance.deleted = FALSE
+		ON CONFLICT (instance_id) DO NOTHING
+	`); err != nil {
+		return nil, errors.Wrapf(err, "failed to seed slow query sync leases")
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE slow_query_sync_lease
+		SET owner_id = $1, expires_at = $2
+		WHERE instance_id IN (
+			SELECT instance_id
+			FROM slow_query_sync_lease
+			WHERE expires_at < $3 AND next_run_at < $3
+			ORDER BY next_run_at ASC
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING instance_id
+	`, ownerID, now.Add(ttl), now, limit)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to claim slow query sync leases")
+	}
+	defer rows.Close()
+
+	var instanceIDs []string
+	for rows.Next() {
+		var instanceID string
+		if err := rows.Scan(&instanceID); err != nil {
+			return nil, err
+		}
+		instanceIDs = append(instanceIDs, instanceID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return instanceIDs, nil
+}
+
+// HeartbeatSlowQuerySyncLease extends the expiry of a lease this owner
+// currently holds, so a long-running sync is not reclaimed by another node.
+func (s *Store) HeartbeatSlowQuerySyncLease(ctx context.Context, instanceID, ownerID string, ttl time.Duration) error {
+	if _, err := s.db.ExecContext(ctx, `
+		UPDATE slow_query_sync_lease
+		SET expires_at = $1
+		WHERE instance_id = $2 AND owner_id = $3
+	`, time.Now().Add(ttl), instanceID, ownerID); err != nil {
+		return errors.Wrapf(err, "failed to heartbeat slow query sync lease for instance %s", instanceID)
+	}
+	return nil
+}
+
+// ReleaseSlowQuerySyncLease releases a lease after a successful sync,
+// resets its failure count, and schedules nextRunAt for the following
+// cycle.
+func (s *Store) ReleaseSlowQuerySyncLease(ctx context.Context, instanceID, ownerID string, nextRunAt time.Time) error {
+	if _, err := s.db.ExecContext(ctx, `
+		UPDATE slow_query_sync_lease
+		SET owner_id = '', expires_at = TIMESTAMP WITH TIME ZONE 'epoch', next_run_at = $1, fail_count = 0
+		WHERE instance_id = $2 AND owner_id = $3
+	`, nextRunAt, instanceID, ownerID); err != nil {
+		return errors.Wrapf(err, "failed to release slow query sync lease for instance %s", instanceID)
+	}
+	return nil
+}
+
+// BackoffSlowQuerySyncLease releases a lease after a failed sync, bumping
+// its failure count and deferring next_run_at by backoffBase*2^fail_count
+// (capped at maxBackoff) so a persistently failing instance is retried less
+// and less often instead of on every tick.
+func (s *Store) BackoffSlowQuerySyncLease(ctx context.Context, instanceID, ownerID string, backoffBase, maxBackoff time.Duration) error {
+	if _, err := s.db.ExecContext(ctx, `
+		UPDATE slow_query_sync_lease
+		SET
+			owner_id = '',
+			expires_at = TIMESTAMP WITH TIME ZONE 'epoch',
+			fail_count = fail_count + 1,
+			next_run_at = NOW() + LEAST($1::double precision * POWER(2, fail_count + 1), $2::double precision) * INTERVAL '1 second'
+		WHERE instance_id = $3 AND owner_id = $4
+	`, backoffBase.Seconds(), maxBackoff.Seconds(), instanceID, ownerID); err != nil {
+		return errors.Wrapf(err, "failed to back off slow query sync lease for instance %s", instanceID)
+	}
+	return nil
+}
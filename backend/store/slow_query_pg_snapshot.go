@@ -0,0 +1,102 @@
+package store
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// PgSlowQuerySnapshotMessage is the last-seen pg_stat_statements counters for
+// a single (database, queryid) pair, used to turn pg_stat_statements'
+// monotonically-accumulating counters into per-window deltas.
+type PgSlowQuerySnapshotMessage struct {
+	DatabaseName  string
+	QueryID       string
+	Calls         int64
+	TotalExecTime float64
+	MaxExecTime   float64
+	Rows          int64
+}
+
+// GetPgSlowQuerySnapshot returns the last-seen pg_stat_statements counters
+// for every (database, queryid) pair previously recorded for instanceID,
+// keyed by "database/queryid".
+func (s *Store) GetPgSlowQuerySnapshot(ctx context.Context, instanceID string) (map[string]*PgSlowQuerySnapshotMessage, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			database_name,
+			query_id,
+			calls,
+			total_exec_time,
+			max_exec_time,
+			rows
+		FROM slow_query_pg_snapshot
+		WHERE instance_id = $1
+	`, instanceID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to query pg slow query snapshot for instance %s", instanceID)
+	}
+	defer rows.Close()
+
+	snapshot := make(map[string]*PgSlowQuerySnapshotMessage)
+	for rows.Next() {
+		var item PgSlowQuerySnapshotMessage
+		if err := rows.Scan(
+			&item.DatabaseName,
+			&item.QueryID,
+			&item.Calls,
+			&item.TotalExecTime,
+			&item.MaxExecTime,
+			&item.Rows,
+		); err != nil {
+			return nil, err
+		}
+		snapshot[item.DatabaseName+"/"+item.QueryID] = &item
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// UpsertPgSlowQuerySnapshot persists the current pg_stat_statements counters
+// for instanceID as the new baseline for the next diff.
+func (s *Store) UpsertPgSlowQuerySnapshot(ctx context.Context, instanceID string, items []*PgSlowQuerySnapshotMessage) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, item := range items {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO slow_query_pg_snapshot (
+				instance_id,
+				database_name,
+				query_id,
+				calls,
+				total_exec_time,
+				max_exec_time,
+				rows
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (instance_id, database_name, query_id) DO UPDATE SET
+				calls = EXCLUDED.calls,
+				total_exec_time = EXCLUDED.total_exec_time,
+				max_exec_time = EXCLUDED.max_exec_time,
+				rows = EXCLUDED.rows
+		`,
+			instanceID,
+			item.DatabaseName,
+			item.QueryID,
+			item.Calls,
+			item.TotalExecTime,
+			item.MaxExecTime,
+			item.Rows,
+		); err != nil {
+			return errors.Wrapf(err, "failed to upsert pg slow query snapshot for instance %s", instanceID)
+		}
+	}
+
+	return tx.Commit()
+}
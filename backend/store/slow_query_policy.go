@@ -0,0 +1,38 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+// SlowQueryPolicyMessage is the slow query policy for a single instance,
+// read by the slow query syncer to decide whether to sync at all and
+// whether to reset the engine's slow-query counters after diffing them.
+type SlowQueryPolicyMessage struct {
+	Active bool
+	// AutoReset, when set, has the syncer reset the engine's cumulative
+	// slow-query counters (e.g. pg_stat_statements) once it has diffed
+	// them, instead of leaving them to accumulate indefinitely; see the
+	// AutoReset handling in syncPostgreSQLSlowQuery
+	// (backend/runner/slowquerysync/syncer.go).
+	AutoReset bool
+}
+
+// GetSlowQueryPolicy returns the slow query policy for instanceID, or nil if
+// none has been set.
+func (s *Store) GetSlowQueryPolicy(ctx context.Context, instanceID string) (*SlowQueryPolicyMessage, error) {
+	var policy SlowQueryPolicyMessage
+	if err := s.db.QueryRowContext(ctx, `
+		SELECT active, auto_reset
+		FROM policy_slow_query
+		WHERE instance_id = $1
+	`, instanceID).Scan(&policy.Active, &policy.AutoReset); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get slow query policy for instance %s", instanceID)
+	}
+	return &policy, nil
+}
@@ -0,0 +1,39 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/bytebase/bytebase/backend/component/dbmiddleware"
+)
+
+// Tx is the transaction handle store's query methods operate on. It is an
+// alias of dbmiddleware.Tx so every BeginTx call, wherever it originates,
+// carries the same tracing/metrics/slow-statement instrumentation as Store's
+// own db connection.
+type Tx = dbmiddleware.Tx
+
+// dbConn is the subset of *dbmiddleware.DB that Store's query methods use.
+// Store depends on this interface, rather than the concrete type, so that
+// wrapping the connection with dbmiddleware in New is the only change
+// needed here: every store_*.go file keeps calling
+// s.db.QueryContext/ExecContext/BeginTx exactly as it already did.
+type dbConn interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error)
+}
+
+// Store is the service for storing Bytebase's own metadata.
+type Store struct {
+	db dbConn
+}
+
+// New creates a new instance of Store, wrapping db with dbmiddleware so
+// every query Store issues is instrumented without changing any callsite.
+func New(db *sql.DB, opts ...dbmiddleware.Option) *Store {
+	return &Store{
+		db: dbmiddleware.New(db, opts...),
+	}
+}
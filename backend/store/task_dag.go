@@ -4,7 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -19,6 +21,382 @@ type TaskDAGMessage struct {
 type TaskDAGFind struct {
 	StageID    *int
 	PipelineID *int
+
+	// OrderByScoreDesc, when set, makes ListTaskDAGTopoOrder sort each Kahn
+	// layer by descending ScoreTask score instead of by task ID, so a
+	// scheduler can promote a hotfix or try job ahead of the rest of a
+	// layer's ready tasks without editing the DAG shape.
+	//
+	// TODO(scheduler): no caller sets this yet; the pipeline scheduler that
+	// would pass OrderByScoreDesc/ScoreAgeWeight through from config.Profile
+	// doesn't live in this package and isn't part of this change.
+	OrderByScoreDesc bool
+	// ScoreAgeWeight is the per-second weight applied to a task's age when
+	// OrderByScoreDesc is set. The caller supplies it directly;
+	// config.Profile has no field for it.
+	ScoreAgeWeight float64
+}
+
+// TaskScoreMessage holds the scheduling hints an operator can set on a task
+// to change its priority relative to the other tasks in the same Kahn
+// layer. AgeSeconds and BlastRadius are not stored here; ScoreTask derives
+// them at read time from the task's creation timestamp and its position in
+// the DAG.
+type TaskScoreMessage struct {
+	TaskID   int
+	ForceRun bool
+	TryJob   bool
+}
+
+const (
+	forceRunScore = 100.0
+	tryJobScore   = 10.0
+)
+
+// UpsertTaskScore sets the ForceRun/TryJob scheduling hints for a task.
+func (s *Store) UpsertTaskScore(ctx context.Context, score *TaskScoreMessage) error {
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO task_score (task_id, force_run, try_job)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (task_id) DO UPDATE SET
+			force_run = EXCLUDED.force_run,
+			try_job = EXCLUDED.try_job
+	`, score.TaskID, score.ForceRun, score.TryJob); err != nil {
+		return errors.Wrapf(err, "failed to upsert task score for task %d", score.TaskID)
+	}
+	return nil
+}
+
+// ScoreTask computes a task's scheduling priority: ForceRun contributes
+// 100.0, TryJob contributes 10.0, and the task's blast radius (the number
+// of downstream tasks, direct or transitive, unblocked by its completion)
+// contributes blastRadius * age * ageWeight, where age is how many seconds
+// the task has existed. Higher scores should run first.
+func (s *Store) ScoreTask(ctx context.Context, taskID int, ageWeight float64) (float64, error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var pipelineID int
+	var createdTs int64
+	var forceRun, tryJob bool
+	if err := tx.QueryRowContext(ctx, `
+		SELECT
+			task.pipeline_id,
+			task.created_ts,
+			COALESCE(task_score.force_run, FALSE),
+			COALESCE(task_score.try_job, FALSE)
+		FROM task
+		LEFT JOIN task_score ON task_score.task_id = task.id
+		WHERE task.id = $1
+	`, taskID).Scan(&pipelineID, &createdTs, &forceRun, &tryJob); err != nil {
+		return 0, errors.Wrapf(err, "failed to look up task %d", taskID)
+	}
+
+	edges, err := s.taskDAGEdges(ctx, tx, pipelineID)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	blastRadius := taskBlastRadius(taskID, edges)
+	return computeTaskScore(forceRun, tryJob, blastRadius, createdTs, time.Now().Unix(), ageWeight), nil
+}
+
+// computeTaskScore is the pure weighting formula behind ScoreTask and
+// ListTaskDAGTopoOrder's OrderByScoreDesc sort: ForceRun contributes 100.0,
+// TryJob contributes 10.0, and blastRadius * age * ageWeight rewards tasks
+// that unblock more downstream work and have been waiting longer. now and
+// createdTs are unix seconds; a negative age (clock skew) is clamped to
+// zero instead of reducing the score.
+func computeTaskScore(forceRun, tryJob bool, blastRadius int, createdTs, now int64, ageWeight float64) float64 {
+	ageSeconds := now - createdTs
+	if ageSeconds < 0 {
+		ageSeconds = 0
+	}
+
+	var score float64
+	if forceRun {
+		score += forceRunScore
+	}
+	if tryJob {
+		score += tryJobScore
+	}
+	score += float64(blastRadius) * float64(ageSeconds) * ageWeight
+	return score
+}
+
+// taskBlastRadius counts the number of distinct tasks transitively
+// downstream of taskID.
+func taskBlastRadius(taskID int, edges map[int][]int) int {
+	visited := make(map[int]bool)
+	var visit func(node int)
+	visit = func(node int) {
+		for _, next := range edges[node] {
+			if !visited[next] {
+				visited[next] = true
+				visit(next)
+			}
+		}
+	}
+	visit(taskID)
+	return len(visited)
+}
+
+// CycleError is returned when an operation would introduce a cycle into a
+// pipeline's task DAG. Nodes lists the task IDs that form the offending
+// cycle, in the order they were revisited during the DFS.
+type CycleError struct {
+	PipelineID int
+	Nodes      []int
+}
+
+func (e *CycleError) Error() string {
+	parts := make([]string, len(e.Nodes))
+	for i, id := range e.Nodes {
+		parts[i] = fmt.Sprintf("%d", id)
+	}
+	return fmt.Sprintf("task dag for pipeline %d has a cycle: %s", e.PipelineID, strings.Join(parts, " -> "))
+}
+
+// color marks the DFS visitation state of a node for cycle detection.
+type color int
+
+const (
+	colorWhite color = iota
+	colorGray
+	colorBlack
+)
+
+// taskDAGEdges fetches the current from_task_id -> to_task_id edge set for
+// the given pipeline within tx. It is the single place that materializes
+// edges so that cycle validation and topological ordering always see the
+// same view of the DAG.
+func (*Store) taskDAGEdges(ctx context.Context, tx *Tx, pipelineID int) (map[int][]int, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+			task_dag.from_task_id,
+			task_dag.to_task_id
+		FROM task_dag
+		JOIN task ON task.id = task_dag.from_task_id
+		WHERE task.pipeline_id = $1
+	`, pipelineID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to query task dag edges")
+	}
+	defer rows.Close()
+
+	edges := make(map[int][]int)
+	for rows.Next() {
+		var fromTaskID, toTaskID int
+		if err := rows.Scan(&fromTaskID, &toTaskID); err != nil {
+			return nil, err
+		}
+		edges[fromTaskID] = append(edges[fromTaskID], toTaskID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return edges, nil
+}
+
+// validateTaskDAGEdges runs a DFS with three-color marking over edges and
+// returns a *CycleError if a back-edge is found.
+func validateTaskDAGEdges(pipelineID int, edges map[int][]int) error {
+	colors := make(map[int]color)
+	var path []int
+
+	var visit func(node int) error
+	visit = func(node int) error {
+		colors[node] = colorGray
+		path = append(path, node)
+		for _, next := range edges[node] {
+			switch colors[next] {
+			case colorGray:
+				// Back-edge found; report the cycle starting from next.
+				cycle := append([]int{}, path...)
+				for i, id := range cycle {
+					if id == next {
+						cycle = cycle[i:]
+						break
+					}
+				}
+				return &CycleError{PipelineID: pipelineID, Nodes: append(cycle, next)}
+			case colorWhite:
+				if err := visit(next); err != nil {
+					return err
+				}
+			case colorBlack:
+				// Already fully explored, no cycle through this edge.
+			}
+		}
+		path = path[:len(path)-1]
+		colors[node] = colorBlack
+		return nil
+	}
+
+	// Iterate over a stable, sorted node order so errors are deterministic.
+	nodes := make([]int, 0, len(edges))
+	for node := range edges {
+		nodes = append(nodes, node)
+	}
+	sort.Ints(nodes)
+
+	for _, node := range nodes {
+		if colors[node] == colorWhite {
+			if err := visit(node); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateTaskDAG fetches the current edge set for pipelineID and returns a
+// *CycleError if it contains a cycle.
+func (s *Store) ValidateTaskDAG(ctx context.Context, pipelineID int) error {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	edges, err := s.taskDAGEdges(ctx, tx, pipelineID)
+	if err != nil {
+		return err
+	}
+	if err := validateTaskDAGEdges(pipelineID, edges); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ListTaskDAGTopoOrder returns the tasks reachable by find's pipeline in
+// Kahn-style layers: each inner slice is the set of tasks whose predecessors
+// have all been emitted by an earlier layer, so tasks within a layer can run
+// in parallel.
+func (s *Store) ListTaskDAGTopoOrder(ctx context.Context, find *TaskDAGFind) ([][]int, error) {
+	if find.PipelineID == nil {
+		return nil, errors.Errorf("PipelineID is required to compute a topological order")
+	}
+
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	edges, err := s.taskDAGEdges(ctx, tx, *find.PipelineID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateTaskDAGEdges(*find.PipelineID, edges); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+			task.id,
+			task.created_ts,
+			COALESCE(task_score.force_run, FALSE),
+			COALESCE(task_score.try_job, FALSE)
+		FROM task
+		LEFT JOIN task_score ON task_score.task_id = task.id
+		WHERE task.pipeline_id = $1
+	`, *find.PipelineID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	indegree := make(map[int]int)
+	createdTs := make(map[int]int64)
+	forceRun := make(map[int]bool)
+	tryJob := make(map[int]bool)
+	for rows.Next() {
+		var taskID int
+		var ts int64
+		var force, try bool
+		if err := rows.Scan(&taskID, &ts, &force, &try); err != nil {
+			return nil, err
+		}
+		indegree[taskID] = 0
+		createdTs[taskID] = ts
+		forceRun[taskID] = force
+		tryJob[taskID] = try
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, tos := range edges {
+		for _, to := range tos {
+			indegree[to]++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	// Precompute each task's score once up front: blast radius and age are
+	// both fixed for the lifetime of this call, so computing them lazily
+	// inside the sort comparator would re-run the transitive-closure DFS
+	// on every pairwise comparison instead of once per task.
+	var scores map[int]float64
+	if find.OrderByScoreDesc {
+		now := time.Now().Unix()
+		scores = make(map[int]float64, len(indegree))
+		for taskID := range indegree {
+			blastRadius := taskBlastRadius(taskID, edges)
+			scores[taskID] = computeTaskScore(forceRun[taskID], tryJob[taskID], blastRadius, createdTs[taskID], now, find.ScoreAgeWeight)
+		}
+	}
+
+	var layers [][]int
+	remaining := len(indegree)
+	for remaining > 0 {
+		var layer []int
+		for taskID, degree := range indegree {
+			if degree == 0 {
+				layer = append(layer, taskID)
+			}
+		}
+		if len(layer) == 0 {
+			// Shouldn't happen since validateTaskDAGEdges already rejected cycles.
+			return nil, errors.Errorf("unable to compute topological order for pipeline %d: remaining tasks form a cycle", *find.PipelineID)
+		}
+		if find.OrderByScoreDesc {
+			sort.Slice(layer, func(i, j int) bool {
+				si, sj := scores[layer[i]], scores[layer[j]]
+				if si != sj {
+					return si > sj
+				}
+				// Break ties deterministically.
+				return layer[i] < layer[j]
+			})
+		} else {
+			sort.Ints(layer)
+		}
+		for _, taskID := range layer {
+			delete(indegree, taskID)
+			remaining--
+		}
+		for _, taskID := range layer {
+			for _, to := range edges[taskID] {
+				if _, ok := indegree[to]; ok {
+					indegree[to]--
+				}
+			}
+		}
+		layers = append(layers, layer)
+	}
+
+	return layers, nil
 }
 
 func (s *Store) RebuildTaskDAG(ctx context.Context, fromTaskIDs []int, toTaskID int) error {
@@ -47,9 +425,32 @@ func (s *Store) RebuildTaskDAG(ctx context.Context, fromTaskIDs []int, toTaskID
 		return err
 	}
 
+	pipelineID, err := taskPipelineID(ctx, tx, toTaskID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to look up pipeline for task %d", toTaskID)
+	}
+	edges, err := s.taskDAGEdges(ctx, tx, pipelineID)
+	if err != nil {
+		return err
+	}
+	if err := validateTaskDAGEdges(pipelineID, edges); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
+// taskPipelineID looks up the pipeline that task belongs to.
+func taskPipelineID(ctx context.Context, tx *Tx, taskID int) (int, error) {
+	var pipelineID int
+	if err := tx.QueryRowContext(ctx, `
+		SELECT pipeline_id FROM task WHERE id = $1
+	`, taskID).Scan(&pipelineID); err != nil {
+		return 0, err
+	}
+	return pipelineID, nil
+}
+
 func (*Store) createTaskDAG(ctx context.Context, tx *Tx, create *TaskDAGMessage) error {
 	query := `
 		INSERT INTO task_dag (
@@ -84,6 +485,18 @@ func (s *Store) CreateTaskDAGV2(ctx context.Context, create *TaskDAGMessage) err
 		return errors.Wrapf(err, "failed to create task dag")
 	}
 
+	pipelineID, err := taskPipelineID(ctx, tx, create.ToTaskID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to look up pipeline for task %d", create.ToTaskID)
+	}
+	edges, err := s.taskDAGEdges(ctx, tx, pipelineID)
+	if err != nil {
+		return err
+	}
+	if err := validateTaskDAGEdges(pipelineID, edges); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return errors.Wrapf(err, "failed to commit tx")
 	}
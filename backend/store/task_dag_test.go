@@ -0,0 +1,186 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTaskDAGEdges(t *testing.T) {
+	tests := []struct {
+		name       string
+		pipelineID int
+		edges      map[int][]int
+		wantCycle  []int
+	}{
+		{
+			name:       "empty",
+			pipelineID: 1,
+			edges:      map[int][]int{},
+		},
+		{
+			name:       "linear chain",
+			pipelineID: 1,
+			edges: map[int][]int{
+				1: {2},
+				2: {3},
+			},
+		},
+		{
+			name:       "diamond",
+			pipelineID: 1,
+			edges: map[int][]int{
+				1: {2, 3},
+				2: {4},
+				3: {4},
+			},
+		},
+		{
+			name:       "self loop",
+			pipelineID: 1,
+			edges: map[int][]int{
+				1: {1},
+			},
+			wantCycle: []int{1, 1},
+		},
+		{
+			name:       "simple cycle",
+			pipelineID: 1,
+			edges: map[int][]int{
+				1: {2},
+				2: {3},
+				3: {1},
+			},
+			wantCycle: []int{1, 2, 3, 1},
+		},
+		{
+			name:       "cycle behind an acyclic branch",
+			pipelineID: 1,
+			edges: map[int][]int{
+				1: {2},
+				2: {3, 4},
+				4: {2},
+			},
+			wantCycle: []int{2, 4, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTaskDAGEdges(tt.pipelineID, tt.edges)
+			if tt.wantCycle == nil {
+				require.NoError(t, err)
+				return
+			}
+			var cycleErr *CycleError
+			require.ErrorAs(t, err, &cycleErr)
+			require.Equal(t, tt.pipelineID, cycleErr.PipelineID)
+			require.Equal(t, tt.wantCycle, cycleErr.Nodes)
+		})
+	}
+}
+
+func TestTaskBlastRadius(t *testing.T) {
+	tests := []struct {
+		name   string
+		taskID int
+		edges  map[int][]int
+		want   int
+	}{
+		{
+			name:   "leaf task",
+			taskID: 3,
+			edges:  map[int][]int{1: {2}, 2: {3}},
+			want:   0,
+		},
+		{
+			name:   "linear chain",
+			taskID: 1,
+			edges:  map[int][]int{1: {2}, 2: {3}},
+			want:   2,
+		},
+		{
+			name:   "diamond counts shared descendant once",
+			taskID: 1,
+			edges:  map[int][]int{1: {2, 3}, 2: {4}, 3: {4}},
+			want:   3,
+		},
+		{
+			name:   "unrelated branch is not counted",
+			taskID: 1,
+			edges:  map[int][]int{1: {2}, 3: {4}},
+			want:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, taskBlastRadius(tt.taskID, tt.edges))
+		})
+	}
+}
+
+func TestComputeTaskScore(t *testing.T) {
+	const hour = int64(3600)
+
+	tests := []struct {
+		name        string
+		forceRun    bool
+		tryJob      bool
+		blastRadius int
+		createdTs   int64
+		now         int64
+		ageWeight   float64
+		want        float64
+	}{
+		{
+			name: "no hints, brand new task scores zero",
+			now:  hour,
+		},
+		{
+			name:     "force run dominates",
+			forceRun: true,
+			now:      hour,
+			want:     forceRunScore,
+		},
+		{
+			name:   "try job adds its fixed weight",
+			tryJob: true,
+			now:    hour,
+			want:   tryJobScore,
+		},
+		{
+			name:        "blast radius and age scale with weight",
+			blastRadius: 4,
+			createdTs:   0,
+			now:         hour,
+			ageWeight:   0.5,
+			want:        4 * float64(hour) * 0.5,
+		},
+		{
+			name:        "clock skew clamps age to zero instead of going negative",
+			blastRadius: 4,
+			createdTs:   hour,
+			now:         0,
+			ageWeight:   0.5,
+			want:        0,
+		},
+		{
+			name:        "force run, try job, and age weighting all combine",
+			forceRun:    true,
+			tryJob:      true,
+			blastRadius: 2,
+			createdTs:   0,
+			now:         hour,
+			ageWeight:   1,
+			want:        forceRunScore + tryJobScore + 2*float64(hour),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := computeTaskScore(tt.forceRun, tt.tryJob, tt.blastRadius, tt.createdTs, tt.now, tt.ageWeight)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}